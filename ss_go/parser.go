@@ -0,0 +1,256 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"various_scheme/ss_go/scanner"
+)
+
+// ==========================================
+// 4. 解析器 (Parser) - 递归下降 + 位置信息
+// ==========================================
+
+// Node 给每个解析出的表达式节点附上源码位置。求值出错时 Node.Eval 把
+// 位置信息拼进错误消息，这样用户看到的是 "Error at foo.scm:12:5: ..."
+// 而不是一条裸错误。
+type Node struct {
+	Pos scanner.Pos
+	Expr
+}
+
+func (n Node) Eval(env *Env) (Val, error) {
+	v, err := n.Expr.Eval(env)
+	if err != nil {
+		return nil, wrapPosErr(n.Pos, err)
+	}
+	return v, nil
+}
+
+// posError 给一个错误打上第一次跨越 Node 边界时的源码位置。表达式嵌套
+// 多少层 Node，错误往外冒泡就会经过多少层 Node 边界——如果每层都无条件
+// 加一个 "Error at ...:" 前缀，稍微深一点的表达式就会在错误里堆出一串
+// 重复的前缀。wrapPosErr 只在错误还没被标记过位置时才包一层，已经带了
+// posError 的错误原样透传，这样最终只会有最内层那一次标记生效。
+type posError struct {
+	pos scanner.Pos
+	err error
+}
+
+func (e *posError) Error() string { return fmt.Sprintf("Error at %s: %v", e.pos, e.err) }
+func (e *posError) Unwrap() error { return e.err }
+
+// wrapPosErr 是 Node 求值出错时唯一的包装入口：Node.Eval 和 evalTail 的
+// Node 分支都必须调用它，而不是各自拼 "Error at %s: %w"，否则同一个错误
+// 会在两条路径上都被包一层。
+func wrapPosErr(pos scanner.Pos, err error) error {
+	if err == nil {
+		return nil
+	}
+	var pe *posError
+	if errors.As(err, &pe) {
+		return err
+	}
+	return &posError{pos: pos, err: err}
+}
+
+// String 委托给被包裹的表达式；除了 Pos 本身，Node 不应该改变任何展示行为。
+func (n Node) String() string {
+	if v, ok := n.Expr.(Val); ok {
+		return v.String()
+	}
+	return fmt.Sprintf("%v", n.Expr)
+}
+
+// unwrap 剥掉 Node 包装，取出真正的语法节点，供需要对 Expr 做类型断言
+// 的地方（define/lambda 的符号、special form 派发）使用。
+func unwrap(e Expr) Expr {
+	if n, ok := e.(Node); ok {
+		return n.Expr
+	}
+	return e
+}
+
+// DottedList 表示 (a . b) 这样的点对语法。它只是解析阶段的语法树节点：
+// quote/quasiquote 会把它转换成真正的 Pair 链（exprToData），直接求值
+// （未加 quote）没有定义好的语义，会报错。
+type DottedList struct {
+	Items []Expr
+	Tail  Expr
+}
+
+func (d DottedList) String() string {
+	parts := make([]string, len(d.Items))
+	for i, e := range d.Items {
+		parts[i] = fmt.Sprintf("%v", e)
+	}
+	return "(" + strings.Join(parts, " ") + " . " + fmt.Sprintf("%v", d.Tail) + ")"
+}
+
+func (d DottedList) Eval(env *Env) (Val, error) {
+	return nil, fmt.Errorf("cannot evaluate a dotted-pair form directly (quote it instead)")
+}
+
+// readExpr 保留原有的“解析一个表达式，返回剩余源码”签名，内部改用
+// scanner 驱动的递归下降解析器，使 fileMode/replMode 不需要跟着改。
+// 如果剩下的源码只有空白和注释、没有真正的表达式了，返回 (nil, "", nil)
+// 而不是报错——调用方（fileMode/replMode）据此判断"读完了"，不是语法错误。
+func readExpr(code string) (Expr, string, error) {
+	sc := scanner.New("", code)
+	tok, err := sc.Next()
+	if err != nil {
+		return nil, "", err
+	}
+	if tok.Type == scanner.EOF {
+		return nil, remainder(sc), nil
+	}
+	expr, err := parseFromToken(tok, sc)
+	if err != nil {
+		return nil, "", err
+	}
+	return expr, remainder(sc), nil
+}
+
+// remainder 把 scanner 还没扫描到的源码还原出来，维持 readExpr 原有的
+// "剩余字符串" 接口。
+func remainder(sc *scanner.Scanner) string {
+	return sc.Rest()
+}
+
+func parseExpr(sc *scanner.Scanner) (Expr, error) {
+	tok, err := sc.Next()
+	if err != nil {
+		return nil, err
+	}
+	return parseFromToken(tok, sc)
+}
+
+func parseFromToken(tok scanner.Token, sc *scanner.Scanner) (Expr, error) {
+	switch tok.Type {
+	case scanner.EOF:
+		return nil, errors.New("unexpected EOF")
+	case scanner.LPAREN:
+		return parseList(sc, tok.Pos)
+	case scanner.HASH_LPAREN:
+		return parseVector(sc, tok.Pos)
+	case scanner.RPAREN:
+		return nil, fmt.Errorf("unexpected ')' at %s", tok.Pos)
+	case scanner.DOT:
+		return nil, fmt.Errorf("unexpected '.' at %s", tok.Pos)
+	case scanner.QUOTE:
+		return parseReaderShortcut(sc, tok.Pos, "quote")
+	case scanner.QUASIQUOTE:
+		return parseReaderShortcut(sc, tok.Pos, "quasiquote")
+	case scanner.UNQUOTE:
+		return parseReaderShortcut(sc, tok.Pos, "unquote")
+	case scanner.UNQUOTE_SPLICE:
+		return parseReaderShortcut(sc, tok.Pos, "unquote-splicing")
+	case scanner.BOOL:
+		return Node{tok.Pos, Bool(tok.Text == "#t")}, nil
+	case scanner.INT:
+		iv, err := strconv.ParseInt(tok.Text, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return Node{tok.Pos, Int(iv)}, nil
+	case scanner.NUMBER:
+		fv, err := strconv.ParseFloat(tok.Text, 64)
+		if err != nil {
+			return nil, err
+		}
+		return Node{tok.Pos, Flt(fv)}, nil
+	case scanner.STRING:
+		return Node{tok.Pos, String(tok.Text)}, nil
+	case scanner.SYMBOL:
+		return Node{tok.Pos, Symbol(tok.Text)}, nil
+	default:
+		return nil, fmt.Errorf("unexpected token %s at %s", tok.Type, tok.Pos)
+	}
+}
+
+// parseReaderShortcut 把 'x `x ,x ,@x 展开成 (quote x) (quasiquote x)
+// (unquote x) (unquote-splicing x)，这里只负责把语法糖摊开，quote 系
+// special form 本身在 scheme.go 里注册。
+func parseReaderShortcut(sc *scanner.Scanner, pos scanner.Pos, head string) (Expr, error) {
+	inner, err := parseExpr(sc)
+	if err != nil {
+		return nil, err
+	}
+	return Node{pos, List{Node{pos, Symbol(head)}, inner}}, nil
+}
+
+func parseList(sc *scanner.Scanner, startPos scanner.Pos) (Expr, error) {
+	items := []Expr{}
+	for {
+		tok, err := sc.Next()
+		if err != nil {
+			return nil, err
+		}
+		switch tok.Type {
+		case scanner.EOF:
+			return nil, errors.New("unbalanced parenthesis: missing ')'")
+		case scanner.RPAREN:
+			return Node{startPos, List(items)}, nil
+		case scanner.DOT:
+			tail, err := parseExpr(sc)
+			if err != nil {
+				return nil, err
+			}
+			closeTok, err := sc.Next()
+			if err != nil {
+				return nil, err
+			}
+			if closeTok.Type != scanner.RPAREN {
+				return nil, fmt.Errorf("expected ')' after dotted tail at %s", closeTok.Pos)
+			}
+			return Node{startPos, DottedList{Items: items, Tail: tail}}, nil
+		default:
+			expr, err := parseFromToken(tok, sc)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, expr)
+		}
+	}
+}
+
+// exprToLiteralVal 把 #(...) 里解析出的节点转成字面量 Val：向量是
+// 自求值的（类似 quote），元素不参与求值。嵌套的 List/DottedList 委托给
+// exprToData，转成 Pair 链，和 quote 对列表数据的处理保持一致。
+func exprToLiteralVal(e Expr) (Val, error) {
+	switch v := unwrap(e).(type) {
+	case Int, Flt, Bool, String, Symbol, Vector:
+		return v.(Val), nil
+	case List, DottedList:
+		return exprToData(v.(Expr))
+	default:
+		return nil, fmt.Errorf("unsupported vector literal element: %T", e)
+	}
+}
+
+func parseVector(sc *scanner.Scanner, startPos scanner.Pos) (Expr, error) {
+	items := []Val{}
+	for {
+		tok, err := sc.Next()
+		if err != nil {
+			return nil, err
+		}
+		if tok.Type == scanner.EOF {
+			return nil, errors.New("unbalanced parenthesis: missing ')' in vector literal")
+		}
+		if tok.Type == scanner.RPAREN {
+			return Node{startPos, Vector{items: items}}, nil
+		}
+		expr, err := parseFromToken(tok, sc)
+		if err != nil {
+			return nil, err
+		}
+		val, err := exprToLiteralVal(expr)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, val)
+	}
+}
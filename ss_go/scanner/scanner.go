@@ -0,0 +1,339 @@
+// Package scanner 把源码切成带位置信息的 token 流，供 parser.go 里的
+// 递归下降解析器消费。所有注释形式（; 行注释、#| |# 块注释、#; 数据注释）
+// 都在这里统一处理，调用方拿到的 token 流里不会再见到注释。
+package scanner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pos 记录一个 token 在源文件里的位置，用于在求值出错时打印
+// "foo.scm:12:5" 这样的提示。
+type Pos struct {
+	File string
+	Line int
+	Col  int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Col)
+}
+
+type TokenType int
+
+const (
+	EOF TokenType = iota
+	LPAREN
+	RPAREN
+	HASH_LPAREN
+	QUOTE
+	QUASIQUOTE
+	UNQUOTE
+	UNQUOTE_SPLICE
+	DOT
+	NUMBER
+	INT
+	STRING
+	BOOL
+	SYMBOL
+)
+
+func (t TokenType) String() string {
+	switch t {
+	case EOF:
+		return "EOF"
+	case LPAREN:
+		return "LPAREN"
+	case RPAREN:
+		return "RPAREN"
+	case HASH_LPAREN:
+		return "HASH_LPAREN"
+	case QUOTE:
+		return "QUOTE"
+	case QUASIQUOTE:
+		return "QUASIQUOTE"
+	case UNQUOTE:
+		return "UNQUOTE"
+	case UNQUOTE_SPLICE:
+		return "UNQUOTE_SPLICE"
+	case DOT:
+		return "DOT"
+	case NUMBER:
+		return "NUMBER"
+	case INT:
+		return "INT"
+	case STRING:
+		return "STRING"
+	case BOOL:
+		return "BOOL"
+	case SYMBOL:
+		return "SYMBOL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Token 是词法分析的最小单元。Text 对 STRING 来说已经完成了转义解码，
+// 对其它类型则是原始字面量文本。
+type Token struct {
+	Type TokenType
+	Text string
+	Pos  Pos
+}
+
+// Scanner 是一个一次性的流式词法分析器：每次 Next() 前进一个 token。
+type Scanner struct {
+	file string
+	src  []rune
+	pos  int
+	line int
+	col  int
+}
+
+func New(file, src string) *Scanner {
+	return &Scanner{file: file, src: []rune(src), line: 1, col: 1}
+}
+
+func isDelim(r rune) bool {
+	switch r {
+	case '(', ')', '"', ';', '\'', '`', ',':
+		return true
+	}
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+func (s *Scanner) peek() (rune, bool) {
+	if s.pos >= len(s.src) {
+		return 0, false
+	}
+	return s.src[s.pos], true
+}
+
+func (s *Scanner) peekAt(off int) (rune, bool) {
+	if s.pos+off >= len(s.src) {
+		return 0, false
+	}
+	return s.src[s.pos+off], true
+}
+
+func (s *Scanner) advance() rune {
+	r := s.src[s.pos]
+	s.pos++
+	if r == '\n' {
+		s.line++
+		s.col = 1
+	} else {
+		s.col++
+	}
+	return r
+}
+
+func (s *Scanner) curPos() Pos {
+	return Pos{File: s.file, Line: s.line, Col: s.col}
+}
+
+// Rest 返回还没被扫描消费的源码，供调用方需要“解析一个表达式，保留剩余
+// 输入”这种增量解析接口时使用（例如 REPL 一行一行喂进来的场景）。
+func (s *Scanner) Rest() string {
+	return string(s.src[s.pos:])
+}
+
+// skipAtmosphere 跳过空白和三种注释，直到下一个真正的 token 开始。
+func (s *Scanner) skipAtmosphere() error {
+	for {
+		r, ok := s.peek()
+		if !ok {
+			return nil
+		}
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			s.advance()
+		case r == ';':
+			for {
+				r, ok := s.peek()
+				if !ok || r == '\n' {
+					break
+				}
+				s.advance()
+			}
+		case r == '#' && s.matches(1, '|'):
+			s.advance()
+			s.advance()
+			if err := s.skipBlockComment(); err != nil {
+				return err
+			}
+		case r == '#' && s.matches(1, ';'):
+			s.advance()
+			s.advance()
+			if err := s.skipDatum(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+func (s *Scanner) matches(off int, want rune) bool {
+	r, ok := s.peekAt(off)
+	return ok && r == want
+}
+
+// skipBlockComment 消费到匹配的 "|#"（不支持嵌套，和大多数玩具实现一致）。
+func (s *Scanner) skipBlockComment() error {
+	for {
+		if _, ok := s.peek(); !ok {
+			return fmt.Errorf("unterminated #| comment at %s", s.curPos())
+		}
+		if s.matches(0, '|') && s.matches(1, '#') {
+			s.advance()
+			s.advance()
+			return nil
+		}
+		s.advance()
+	}
+}
+
+// skipDatum 丢弃紧跟在 #; 后面的一整个数据项（一个原子，或一对平衡括号）。
+func (s *Scanner) skipDatum() error {
+	tok, err := s.Next()
+	if err != nil {
+		return err
+	}
+	switch tok.Type {
+	case LPAREN, HASH_LPAREN:
+		depth := 1
+		for depth > 0 {
+			t, err := s.Next()
+			if err != nil {
+				return err
+			}
+			switch t.Type {
+			case LPAREN, HASH_LPAREN:
+				depth++
+			case RPAREN:
+				depth--
+			case EOF:
+				return fmt.Errorf("unterminated datum after #; at %s", tok.Pos)
+			}
+		}
+	}
+	return nil
+}
+
+// Next 返回下一个 token，忽略空白和注释。
+func (s *Scanner) Next() (Token, error) {
+	if err := s.skipAtmosphere(); err != nil {
+		return Token{}, err
+	}
+
+	r, ok := s.peek()
+	if !ok {
+		return Token{Type: EOF, Pos: s.curPos()}, nil
+	}
+	pos := s.curPos()
+
+	switch {
+	case r == '(':
+		s.advance()
+		return Token{Type: LPAREN, Text: "(", Pos: pos}, nil
+	case r == ')':
+		s.advance()
+		return Token{Type: RPAREN, Text: ")", Pos: pos}, nil
+	case r == '\'':
+		s.advance()
+		return Token{Type: QUOTE, Text: "'", Pos: pos}, nil
+	case r == '`':
+		s.advance()
+		return Token{Type: QUASIQUOTE, Text: "`", Pos: pos}, nil
+	case r == ',':
+		s.advance()
+		if s.matches(0, '@') {
+			s.advance()
+			return Token{Type: UNQUOTE_SPLICE, Text: ",@", Pos: pos}, nil
+		}
+		return Token{Type: UNQUOTE, Text: ",", Pos: pos}, nil
+	case r == '"':
+		return s.readString(pos)
+	case r == '#' && s.matches(1, '('):
+		s.advance()
+		s.advance()
+		return Token{Type: HASH_LPAREN, Text: "#(", Pos: pos}, nil
+	default:
+		return s.readAtom(pos)
+	}
+}
+
+func (s *Scanner) readString(pos Pos) (Token, error) {
+	s.advance() // 跳过开头的 "
+	var sb strings.Builder
+	for {
+		r, ok := s.peek()
+		if !ok {
+			return Token{}, fmt.Errorf("unterminated string literal at %s", pos)
+		}
+		if r == '"' {
+			s.advance()
+			return Token{Type: STRING, Text: sb.String(), Pos: pos}, nil
+		}
+		if r == '\\' {
+			s.advance()
+			esc, ok := s.peek()
+			if !ok {
+				return Token{}, fmt.Errorf("unterminated string literal at %s", pos)
+			}
+			s.advance()
+			switch esc {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case '"':
+				sb.WriteByte('"')
+			case '\\':
+				sb.WriteByte('\\')
+			default:
+				sb.WriteRune(esc)
+			}
+			continue
+		}
+		sb.WriteRune(r)
+		s.advance()
+	}
+}
+
+func (s *Scanner) readAtom(pos Pos) (Token, error) {
+	var sb strings.Builder
+	for {
+		r, ok := s.peek()
+		if !ok || isDelim(r) {
+			break
+		}
+		sb.WriteRune(r)
+		s.advance()
+	}
+	text := sb.String()
+	if text == "" {
+		return Token{}, fmt.Errorf("unexpected character %q at %s", r0(s), pos)
+	}
+	if text == "#t" || text == "#f" {
+		return Token{Type: BOOL, Text: text, Pos: pos}, nil
+	}
+	if text == "." {
+		return Token{Type: DOT, Text: text, Pos: pos}, nil
+	}
+	if _, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return Token{Type: INT, Text: text, Pos: pos}, nil
+	}
+	if _, err := strconv.ParseFloat(text, 64); err == nil {
+		return Token{Type: NUMBER, Text: text, Pos: pos}, nil
+	}
+	return Token{Type: SYMBOL, Text: text, Pos: pos}, nil
+}
+
+func r0(s *Scanner) rune {
+	r, _ := s.peek()
+	return r
+}
@@ -2,21 +2,24 @@ package main
 
 import (
 	"bufio"
-	"errors"
 	"fmt"
 	"os"
-	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
-	"unicode"
+	"sync"
 )
 
 // ==========================================
 // 1. 类型定义与环境 (Types & Environment)
 // ==========================================
 
+// Env.store 在有了 future 之后可能被多个 goroutine 并发读写（例如 future
+// 内部的 define/set! 会修改闭包捕获的那个 frame），所以这里用 RWMutex
+// 保护；Get 在自身命中时才持锁读，miss 时照旧沿 parent 链查找。
 type Env struct {
 	parent *Env
+	mu     sync.RWMutex
 	store  map[string]Val
 }
 
@@ -25,7 +28,10 @@ func NewEnv(parent *Env) *Env {
 }
 
 func (e *Env) Get(name string) (Val, bool) {
-	if v, ok := e.store[name]; ok {
+	e.mu.RLock()
+	v, ok := e.store[name]
+	e.mu.RUnlock()
+	if ok {
 		return v, true
 	}
 	if e.parent != nil {
@@ -35,17 +41,82 @@ func (e *Env) Get(name string) (Val, bool) {
 }
 
 func (e *Env) Set(name string, val Val) {
+	e.mu.Lock()
 	e.store[name] = val
+	e.mu.Unlock()
 }
 
+// SetMutate 沿 env 链向上找到已经绑定 name 的那一帧并原地覆盖其值，供
+// set! 使用。它和 Set 不同：Set 总是写入当前帧（define 语义），而
+// SetMutate 必须改到变量"原本所在"的那一帧，否则会在当前作用域新建一个
+// 遮蔽外层同名变量的绑定，而不是真正发生了赋值。
+func (e *Env) SetMutate(name string, val Val) bool {
+	e.mu.Lock()
+	if _, ok := e.store[name]; ok {
+		e.store[name] = val
+		e.mu.Unlock()
+		return true
+	}
+	e.mu.Unlock()
+	if e.parent != nil {
+		return e.parent.SetMutate(name, val)
+	}
+	return false
+}
+
+// workerPool 是 parallel-map 用的固定大小 goroutine 池，大小取 GOMAXPROCS。
+// future 不用它：parallel-sum 这类分治递归里，一个 future 的 body 经常会
+// 再 force 另一个嵌套的 future，如果都挤在同一个固定大小的池子里，所有
+// worker 都可能同时阻塞在 force 上等待彼此，池子就再也调度不出新任务，
+// 直接死锁（Go runtime 会报 "all goroutines are asleep"）。parallel-map
+// 提交的任务彼此独立、不互相 force，用固定池没有这个问题。
+type workerPoolT struct {
+	tasks chan func()
+}
+
+func newWorkerPool(size int) *workerPoolT {
+	p := &workerPoolT{tasks: make(chan func(), 256)}
+	for i := 0; i < size; i++ {
+		go p.run()
+	}
+	return p
+}
+
+func (p *workerPoolT) run() {
+	for task := range p.tasks {
+		task()
+	}
+}
+
+func (p *workerPoolT) submit(task func()) {
+	p.tasks <- task
+}
+
+var workerPool = newWorkerPool(runtime.GOMAXPROCS(0))
+
 type Val interface {
 	String() string // Go 惯用 String() 而非 repr()
 }
 
+// Num 把算术/比较操作统一到一个小接口上，让 + - * / = < > 可以对
+// Int 和 Flt 多态派发，而不必把所有数字硬转成 float64。
+type Num interface {
+	Val
+	add(other Num) Num
+	sub(other Num) Num
+	mul(other Num) Num
+	div(other Num) Num
+	neg() Num
+	sign() int // 负/零/正 分别对应 -1/0/1，compareOp 靠它实现 = < >
+}
+
 type (
-	Number  float64
+	Int     int64
+	Flt     float64
 	Bool    bool
 	Symbol  string
+	String  string
+	Vector  struct{ items []Val }
 	List    []Expr
 	Builtin func([]Val) (Val, error) // 简化原生函数签名
 	Lambda  struct {
@@ -55,7 +126,156 @@ type (
 	}
 )
 
-func (n Number) String() string { return strconv.FormatFloat(float64(n), 'f', -1, 64) }
+// Future 是 (future expr) 的运行时句柄：expr 在 future 自己开的 goroutine
+// 里求值，done 在结果写完后关闭。force 只需要 <-done，不需要
+// sync.Once——计算只会被提交一次。
+type Future struct {
+	done   chan struct{}
+	result Val
+	err    error
+}
+
+// Promise 是 (delay expr) 的运行时句柄：与 Future 相反，expr 直到第一次
+// force 才会求值，之后用 once 记住结果，重复 force 不会重复计算。
+type Promise struct {
+	once   sync.Once
+	expr   Expr
+	env    *Env
+	result Val
+	err    error
+}
+
+func (f *Future) String() string  { return "<future>" }
+func (p *Promise) String() string { return "<promise>" }
+
+// futurePanicToErr 把 future 的 goroutine 里逃出来的 panic 转成 fut.err，
+// 不能让它就这么展开到 goroutine 顶上——那样整个进程都会跟着崩掉。最常见
+// 的情况是 body 里调用了一个 call/cc 捕获的 continuation（contInvocation）：
+// continuation 是逃逸续延，设计上就不能跨 goroutine 跳回去，所以统一报成
+// 和"续延已经失效"一样的 Scheme 层错误，而不是让它原样 panic 出去。
+func futurePanicToErr(r interface{}) error {
+	if _, ok := r.(contInvocation); ok {
+		return fmt.Errorf("continuation invoked outside its dynamic extent")
+	}
+	return fmt.Errorf("future: panic: %v", r)
+}
+
+// contToken 是一次 call/cc 捕获的唯一标识，只靠指针恒等比较，不需要真的
+// 分配一个 id。
+type contToken struct{}
+
+// Continuation 是 call/cc 捕获的续延：调用 (k v) 会 panic 一个
+// contInvocation，展开 Go 调用栈直接跳回对应 call/cc 自己的调用点，把
+// 整个 call/cc 表达式的值替换成 v。
+//
+// 这是"逃逸续延"（escape continuation），只能向上跳出、一次性使用：
+// 对应的 call/cc 一旦正常返回，它的 token 就从 activeConts 里弹出了，
+// 之后再调用同一个 k 不会、也不可能真的跳回那个已经消失的调用帧——那是
+// 完整的多次调用（re-entrant/"multi-shot"）续延才能做到的事，这里只报
+// 一个 Scheme 层的错误。call/cc 也不能从 future 里逃逸到另一个
+// goroutine：panic 没法跨 goroutine 展开到原来的调用栈，future 自己的
+// recover（见 futurePanicToErr）会把这种情况同样转成一个 Scheme 层的
+// 错误，而不是让 panic 逃出 goroutine 崩掉整个进程。
+type Continuation struct {
+	token *contToken
+}
+
+func (c *Continuation) String() string { return "<continuation>" }
+
+// contInvocation 是调用续延时 panic 出去的载荷，call/cc 按 token 匹配
+// 自己的 defer/recover，token 不匹配就继续向外层 panic。
+type contInvocation struct {
+	token *contToken
+	val   Val
+}
+
+// activeConts 记录当前仍在动态范围内（对应 call/cc 尚未返回）的续延
+// token。调用一个已经不在这里面的续延说明它已经逃出了自己的动态范围，
+// 只能报错，不能真的跳过去。
+var (
+	activeContsMu sync.Mutex
+	activeConts   []*contToken
+)
+
+func pushActiveCont(tok *contToken) {
+	activeContsMu.Lock()
+	activeConts = append(activeConts, tok)
+	activeContsMu.Unlock()
+}
+
+func popActiveCont(tok *contToken) {
+	activeContsMu.Lock()
+	for i := len(activeConts) - 1; i >= 0; i-- {
+		if activeConts[i] == tok {
+			activeConts = append(activeConts[:i], activeConts[i+1:]...)
+			break
+		}
+	}
+	activeContsMu.Unlock()
+}
+
+func isActiveCont(tok *contToken) bool {
+	activeContsMu.Lock()
+	defer activeContsMu.Unlock()
+	for _, t := range activeConts {
+		if t == tok {
+			return true
+		}
+	}
+	return false
+}
+
+// invokeContinuation 触发一次转义跳转：token 还在自己的动态范围内就
+// panic 出去，让对应 call/cc 的 recover 接住；已经逃出去了就只是个
+// Scheme 层的错误。
+func invokeContinuation(c *Continuation, args []Val) (Val, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("continuation invoked with %d args, expected 1", len(args))
+	}
+	if !isActiveCont(c.token) {
+		return nil, fmt.Errorf("continuation invoked outside its dynamic extent")
+	}
+	panic(contInvocation{token: c.token, val: args[0]})
+}
+
+// callCC 实现 call/cc：把 procExpr 求值成一个过程后立刻用捕获到的
+// Continuation 调用它。过程正常返回就是 call/cc 的值；过程在自己的动态
+// 范围内任意深度（哪怕隔着好几层非尾调用的 Go 栈帧）调用了这个
+// Continuation，都会通过 panic/recover 直接跳回这里。
+func callCC(args []Expr, env *Env) (Val, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("call/cc takes 1 arg")
+	}
+	procVal, err := args[0].Eval(env)
+	if err != nil {
+		return nil, err
+	}
+	tok := &contToken{}
+	cont := &Continuation{token: tok}
+	pushActiveCont(tok)
+	defer popActiveCont(tok)
+
+	var result Val
+	var resultErr error
+	func() {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			inv, ok := r.(contInvocation)
+			if !ok || inv.token != tok {
+				panic(r) // 不是自己的 token，继续往外层展开
+			}
+			result, resultErr = inv.val, nil
+		}()
+		result, resultErr = applyVal(procVal, []Val{cont})
+	}()
+	return result, resultErr
+}
+
+func (i Int) String() string { return strconv.FormatInt(int64(i), 10) }
+func (f Flt) String() string { return strconv.FormatFloat(float64(f), 'f', -1, 64) }
 func (b Bool) String() string {
 	if b {
 		return "#t"
@@ -63,6 +283,19 @@ func (b Bool) String() string {
 	return "#f"
 }
 func (s Symbol) String() string { return string(s) }
+
+// String.String() 按 write 语法带引号输出，转义内部的 \ 和 "。
+func (s String) String() string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(string(s))
+	return `"` + escaped + `"`
+}
+func (v Vector) String() string {
+	parts := make([]string, len(v.items))
+	for i, it := range v.items {
+		parts[i] = it.String()
+	}
+	return "#(" + strings.Join(parts, " ") + ")"
+}
 func (l List) String() string {
 	parts := make([]string, len(l))
 	for i, e := range l {
@@ -73,6 +306,182 @@ func (l List) String() string {
 func (b Builtin) String() string { return "<builtin>" }
 func (l Lambda) String() string  { return "<lambda>" }
 
+// Pair 是真正的 cons cell：quote/quasiquote 产生的列表数据，以及
+// cons/car/cdr/list/map 等 builtin 操作的对象，都是 Car/Cdr 这样不透明地
+// 链起来的 Pair 链——和 List（只是解析阶段函数调用语法的 AST 节点）是
+// 两码事，List 从来不会作为运行时值出现。
+type Pair struct {
+	Car Val
+	Cdr Val
+}
+
+// nilType 是空表 '() 的运行时类型，用全局单例 Nil 表示，这样 null? 和
+// pairToSlice 的终止条件都可以直接做类型断言，不需要额外的哨兵字段。
+type nilType struct{}
+
+// Nil 是唯一的空表值。
+var Nil Val = nilType{}
+
+func (nilType) String() string { return "()" }
+
+// Pair.String() 按正常表打印；如果 Cdr 链最终没有以 Nil 收尾（improper
+// list），退化成 (a b . c) 这样的点对语法。
+func (p Pair) String() string {
+	var sb strings.Builder
+	sb.WriteByte('(')
+	sb.WriteString(p.Car.String())
+	rest := p.Cdr
+	for {
+		switch r := rest.(type) {
+		case nilType:
+			sb.WriteByte(')')
+			return sb.String()
+		case Pair:
+			sb.WriteByte(' ')
+			sb.WriteString(r.Car.String())
+			rest = r.Cdr
+		default:
+			sb.WriteString(" . ")
+			sb.WriteString(r.String())
+			sb.WriteByte(')')
+			return sb.String()
+		}
+	}
+}
+
+// sliceToPairs 把一个 Go slice 拼成一条以 Nil 收尾的 Pair 链。
+func sliceToPairs(items []Val) Val {
+	var result Val = Nil
+	for i := len(items) - 1; i >= 0; i-- {
+		result = Pair{Car: items[i], Cdr: result}
+	}
+	return result
+}
+
+// pairToSlice 把一条 Pair 链摊平成 Go slice，遇到非 Nil 收尾的 improper
+// list（比如 (1 . 2)）就报错——list/length/map/filter/foldl/append/
+// reverse 和 quasiquote 的 unquote-splicing 都要求是一个 proper list。
+func pairToSlice(v Val) ([]Val, error) {
+	var items []Val
+	for {
+		switch p := v.(type) {
+		case nilType:
+			return items, nil
+		case Pair:
+			items = append(items, p.Car)
+			v = p.Cdr
+		default:
+			return nil, fmt.Errorf("expected a proper list, got improper tail %v", v)
+		}
+	}
+}
+
+// exprToData 把解析阶段产生的语法节点转换成对应的 quote 数据：List 变成
+// 以 Nil 收尾的 Pair 链，DottedList 的尾部变成真正的 Cdr（而不是 Nil），
+// 原子（Int/Flt/Bool/String/Symbol/Vector）自己就是数据，递归处理嵌套
+// 结构。这是 readExpr 产生的语法树和 Eval 消费的运行时值之间的边界。
+func exprToData(e Expr) (Val, error) {
+	switch v := unwrap(e).(type) {
+	case List:
+		items := make([]Val, len(v))
+		for i, item := range v {
+			val, err := exprToData(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = val
+		}
+		return sliceToPairs(items), nil
+	case DottedList:
+		tail, err := exprToData(v.Tail)
+		if err != nil {
+			return nil, err
+		}
+		result := tail
+		for i := len(v.Items) - 1; i >= 0; i-- {
+			item, err := exprToData(v.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			result = Pair{Car: item, Cdr: result}
+		}
+		return result, nil
+	case Val:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("cannot quote %T", e)
+	}
+}
+
+// toFlt 把任意 Num 转成 float64，供跨类型运算（int 与 float 混算）使用。
+func toFlt(n Num) float64 {
+	switch v := n.(type) {
+	case Int:
+		return float64(v)
+	case Flt:
+		return float64(v)
+	default:
+		panic(fmt.Sprintf("toFlt: unknown Num implementation %T", n))
+	}
+}
+
+// 提升规则：int⊕int→int，int⊕float→float。
+
+func (i Int) add(o Num) Num {
+	if oi, ok := o.(Int); ok {
+		return i + oi
+	}
+	return Flt(float64(i) + toFlt(o))
+}
+func (i Int) sub(o Num) Num {
+	if oi, ok := o.(Int); ok {
+		return i - oi
+	}
+	return Flt(float64(i) - toFlt(o))
+}
+func (i Int) mul(o Num) Num {
+	if oi, ok := o.(Int); ok {
+		return i * oi
+	}
+	return Flt(float64(i) * toFlt(o))
+}
+
+// div 在两个整数能整除时仍返回 Int，否则退化为 Flt——我们没有有理数类型，
+// 这样既保留了 (/ 4 2) => 2 的直觉，又不会让 (/ 1 2) 截断成 0。
+func (i Int) div(o Num) Num {
+	if oi, ok := o.(Int); ok && oi != 0 && i%oi == 0 {
+		return i / oi
+	}
+	return Flt(float64(i) / toFlt(o))
+}
+func (i Int) neg() Num { return -i }
+func (i Int) sign() int {
+	switch {
+	case i > 0:
+		return 1
+	case i < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func (f Flt) add(o Num) Num { return f + Flt(toFlt(o)) }
+func (f Flt) sub(o Num) Num { return f - Flt(toFlt(o)) }
+func (f Flt) mul(o Num) Num { return f * Flt(toFlt(o)) }
+func (f Flt) div(o Num) Num { return f / Flt(toFlt(o)) }
+func (f Flt) neg() Num      { return -f }
+func (f Flt) sign() int {
+	switch {
+	case f > 0:
+		return 1
+	case f < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
 // ==========================================
 // 2. 表达式与求值 (Expression & Evaluation)
 // ==========================================
@@ -82,8 +491,11 @@ type Expr interface {
 }
 
 // 原子类型自求值
-func (n Number) Eval(env *Env) (Val, error) { return n, nil }
+func (i Int) Eval(env *Env) (Val, error)    { return i, nil }
+func (f Flt) Eval(env *Env) (Val, error)    { return f, nil }
 func (b Bool) Eval(env *Env) (Val, error)   { return b, nil }
+func (s String) Eval(env *Env) (Val, error) { return s, nil }
+func (v Vector) Eval(env *Env) (Val, error) { return v, nil }
 func (s Symbol) Eval(env *Env) (Val, error) {
 	if v, ok := env.Get(string(s)); ok {
 		return v, nil
@@ -91,40 +503,124 @@ func (s Symbol) Eval(env *Env) (Val, error) {
 	return nil, fmt.Errorf("undefined symbol: %s", s)
 }
 
-// 列表求值（核心逻辑）
+// isFalse 是 Scheme 唯一的假值判断规则：只有 #f 为假，0/0.0/空字符串等都为真。
+func isFalse(v Val) bool {
+	b, ok := v.(Bool)
+	return ok && !bool(b)
+}
+
+// TailCall 是尾调用 trampoline 内部使用的 sentinel：求值走到尾位置的一次
+// lambda 应用时，不直接递归调用 evalInTail（那样 Go 调用栈会随 Scheme 的
+// 尾递归深度一起长），而是把"接下来该对哪个 body、在哪个 env 下继续求值"
+// 打包成 TailCall 返回，由 evalInTail 的循环展开成迭代。它只应该出现在
+// evalTail/evalInTail 内部；Eval() 在返回前总会把 TailCall 展开到底，
+// 所以不会泄漏给其余代码。
+type TailCall struct {
+	expr Expr
+	env  *Env
+}
+
+func (t TailCall) String() string { return "<tailcall>" }
+
+// 列表求值（核心逻辑）。真正的求值循环在 evalInTail 里，这里只是把它
+// 跑到底，对外呈现的仍然是"一次调用拿到最终值"的普通 Eval 接口。
 func (l List) Eval(env *Env) (Val, error) {
+	return evalInTail(l, env)
+}
+
+// evalInTail 是尾调用 trampoline 的驱动循环：反复对 (expr, env) 求值，
+// 只要命中尾位置的 lambda 应用（evalTail 返回 TailCall），就地替换
+// expr/env 继续下一轮，而不是递归调用自己——这样 Scheme 里 1e7 层深的
+// 尾递归也只占用一个 Go 栈帧。
+func evalInTail(expr Expr, env *Env) (Val, error) {
+	for {
+		v, err := evalTail(expr, env)
+		if err != nil {
+			return nil, err
+		}
+		tc, ok := v.(TailCall)
+		if !ok {
+			return v, nil
+		}
+		expr, env = tc.expr, tc.env
+	}
+}
+
+// evalTail 对 expr 求值，但在尾位置遇到 lambda 应用时返回 TailCall 而不是
+// 递归求出最终值——调用方必须是 evalInTail 的循环或另一个会继续传播尾
+// 位置的 evalTail 调用，否则 TailCall 这个内部 sentinel 就泄漏出去了。
+// Node/List 之外的类型没有"尾位置"可言，直接退化成普通 Eval。
+func evalTail(expr Expr, env *Env) (Val, error) {
+	switch e := expr.(type) {
+	case Node:
+		v, err := evalTail(e.Expr, env)
+		if err != nil {
+			return nil, wrapPosErr(e.Pos, err)
+		}
+		return v, nil
+	case List:
+		return evalListTail(e, env)
+	default:
+		return expr.Eval(env)
+	}
+}
+
+// evalListTail 是列表求值的核心派发逻辑：special form 优先，否则当作
+// 函数调用处理。函数调用走到这里时参数已经全部求值完毕，真正的应用交给
+// applyTail——如果是 Lambda，applyTail 返回 TailCall 而不是直接求值 body。
+func evalListTail(l List, env *Env) (Val, error) {
 	if len(l) == 0 {
 		return nil, fmt.Errorf("cannot evaluate empty list")
 	}
 
 	// 1. 检查是否为 Special Form (if, define, lambda, etc.)
 	// 这里为了简化，假设 Special Form 的第一个元素必须是 Symbol
-	if headSym, ok := l[0].(Symbol); ok {
+	if headSym, ok := unwrap(l[0]).(Symbol); ok {
 		if handler, ok := specialForms[string(headSym)]; ok {
 			return handler(l[1:], env)
 		}
 	}
 
-	// 2. 函数调用：先求值第一个元素
+	// 2. 函数调用：先求值第一个元素（操作符位置不是尾位置）
 	fnVal, err := l[0].Eval(env)
 	if err != nil {
 		return nil, err
 	}
 
 	// 3. 求值所有参数
-	args := make([]Val, 0, len(l)-1)
-	for _, expr := range l[1:] {
+	args, err := evalArgs(l[1:], env)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. 应用函数：Lambda 在尾位置被打包成 TailCall 交给外层循环
+	return applyTail(fnVal, args)
+}
+
+// evalArgs 依次对 exprs 求值，任何一个出错就整体短路返回。
+func evalArgs(exprs []Expr, env *Env) ([]Val, error) {
+	args := make([]Val, 0, len(exprs))
+	for _, expr := range exprs {
 		arg, err := expr.Eval(env)
 		if err != nil {
 			return nil, err
 		}
 		args = append(args, arg)
 	}
+	return args, nil
+}
 
-	// 4. 执行函数
+// applyVal 把“已求值的函数值 + 已求值的参数”应用起来并立即求出最终值，从
+// List.Eval 里抽出来，好让 parallel-map/force 这类 builtin 也能在普通 Go
+// 函数（以及 worker goroutine）里直接调用 Scheme 函数。Lambda 分支通过
+// evalInTail 走 trampoline，所以即使被调用的函数体内部是深层尾递归也不会
+// 撑爆 Go 调用栈。
+func applyVal(fnVal Val, args []Val) (Val, error) {
 	switch fn := fnVal.(type) {
 	case Builtin:
 		return fn(args)
+	case *Continuation:
+		return invokeContinuation(fn, args)
 	case Lambda:
 		if len(args) != len(fn.params) {
 			return nil, fmt.Errorf("arity mismatch: expected %d args, got %d", len(fn.params), len(args))
@@ -134,10 +630,28 @@ func (l List) Eval(env *Env) (Val, error) {
 		for i, param := range fn.params {
 			newEnv.Set(param, args[i])
 		}
-		return fn.body.Eval(newEnv)
+		return evalInTail(fn.body, newEnv)
 	default:
-		return nil, fmt.Errorf("not a function: %s", l[0])
+		return nil, fmt.Errorf("not a function: %v", fnVal)
+	}
+}
+
+// applyTail 和 applyVal 做一样的事，但用在尾位置：Lambda 应用不求值 body，
+// 只是把"新 body + 新 env"打包成 TailCall 交回 evalInTail 的循环，避免
+// 递归调用 evalInTail 本身。Builtin 和错误分支直接委托给 applyVal。
+func applyTail(fnVal Val, args []Val) (Val, error) {
+	lam, ok := fnVal.(Lambda)
+	if !ok {
+		return applyVal(fnVal, args)
+	}
+	if len(args) != len(lam.params) {
+		return nil, fmt.Errorf("arity mismatch: expected %d args, got %d", len(lam.params), len(args))
 	}
+	newEnv := NewEnv(lam.env)
+	for i, param := range lam.params {
+		newEnv.Set(param, args[i])
+	}
+	return TailCall{expr: lam.body, env: newEnv}, nil
 }
 
 // ==========================================
@@ -147,117 +661,424 @@ func (l List) Eval(env *Env) (Val, error) {
 // SpecialForm 处理器接收未求值的参数 AST
 type SpecialForm func(args []Expr, env *Env) (Val, error)
 
-var specialForms = map[string]SpecialForm{
-	"define": func(args []Expr, env *Env) (Val, error) {
-		if len(args) != 2 {
-			return nil, fmt.Errorf("define takes 2 args")
-		}
-		sym, ok := args[0].(Symbol)
-		if !ok {
-			return nil, fmt.Errorf("define first arg must be symbol")
+// specialForms 在 init() 里（而不是直接作为 var 初始化表达式）赋值，
+// 因为这些 handler 闭包引用了 evalTail/evalListTail，而它们又会读取
+// specialForms 本身——作为一条 var 初始化表达式会被 Go 判定成初始化环，
+// 挪到 init() 里就只是一次普通的运行时赋值，没有这个限制。
+var specialForms map[string]SpecialForm
+
+func init() {
+	specialForms = map[string]SpecialForm{
+		"define": func(args []Expr, env *Env) (Val, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("define takes 2 args")
+			}
+			sym, ok := unwrap(args[0]).(Symbol)
+			if !ok {
+				return nil, fmt.Errorf("define first arg must be symbol")
+			}
+			val, err := args[1].Eval(env)
+			if err != nil {
+				return nil, err
+			}
+			env.Set(string(sym), val)
+			return nil, nil // define 返回 nil
+		},
+		"if": func(args []Expr, env *Env) (Val, error) {
+			if len(args) != 3 {
+				return nil, fmt.Errorf("if takes 3 args")
+			}
+			cond, err := args[0].Eval(env)
+			if err != nil {
+				return nil, err
+			}
+			if isFalse(cond) {
+				return evalTail(args[2], env)
+			}
+			return evalTail(args[1], env)
+		},
+		"lambda": func(args []Expr, env *Env) (Val, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("lambda takes 2 args")
+			}
+			paramsNode, ok := unwrap(args[0]).(List)
+			if !ok {
+				return nil, fmt.Errorf("lambda params must be a list")
+			}
+			params := make([]string, len(paramsNode))
+			for i, p := range paramsNode {
+				sym, ok := unwrap(p).(Symbol)
+				if !ok {
+					return nil, fmt.Errorf("param must be symbol")
+				}
+				params[i] = string(sym)
+			}
+			return Lambda{params: params, body: args[1], env: env}, nil
+		},
+		// and/or 短路于 #f/非#f，返回最后一个被求值的表达式的值（而非强制布尔
+		// 化）。只有真正跑到的最后一个表达式处在尾位置；短路提前返回的那些
+		// 不是，所以用 evalTail 只包最后一个。
+		"and": func(args []Expr, env *Env) (Val, error) {
+			if len(args) == 0 {
+				return Bool(true), nil
+			}
+			for _, a := range args[:len(args)-1] {
+				v, err := a.Eval(env)
+				if err != nil {
+					return nil, err
+				}
+				if isFalse(v) {
+					return v, nil
+				}
+			}
+			return evalTail(args[len(args)-1], env)
+		},
+		"or": func(args []Expr, env *Env) (Val, error) {
+			if len(args) == 0 {
+				return Bool(false), nil
+			}
+			for _, a := range args[:len(args)-1] {
+				v, err := a.Eval(env)
+				if err != nil {
+					return nil, err
+				}
+				if !isFalse(v) {
+					return v, nil
+				}
+			}
+			return evalTail(args[len(args)-1], env)
+		},
+		// future 立即为 expr 另起一个 goroutine 后台求值，(force f) 再取结果。
+		// 故意不走共用的 workerPool：池子大小固定，嵌套 future（parallel-sum
+		// 式分治递归里很常见）会让所有 worker 一起阻塞在 force 上互相等待，
+		// 直接死锁；每个 future 独立开 goroutine 就没有这个问题，Go
+		// runtime 本来就是为大量并发 goroutine 设计的。这偏离了最初"固定大小
+		// 为 GOMAXPROCS 的 worker pool"的设计，是有意的取舍：无界 goroutine
+		// 换来了嵌套 future 不死锁，没有对并发数做限流。
+		"future": func(args []Expr, env *Env) (Val, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("future takes 1 args")
+			}
+			expr := args[0]
+			fut := &Future{done: make(chan struct{})}
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						fut.err = futurePanicToErr(r)
+					}
+					close(fut.done)
+				}()
+				fut.result, fut.err = expr.Eval(env)
+			}()
+			return fut, nil
+		},
+		// delay 把 expr 包成惰性 promise，expr 直到第一次 force 才会求值。
+		"delay": func(args []Expr, env *Env) (Val, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("delay takes 1 args")
+			}
+			return &Promise{expr: args[0], env: env}, nil
+		},
+		// begin 顺序求值，只有最后一个表达式在尾位置。
+		"begin": func(args []Expr, env *Env) (Val, error) {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("begin takes at least 1 arg")
+			}
+			return evalBody(args, env)
+		},
+		// let 的绑定值都在外层 env 下求值（互相看不见），body 在一个新建的
+		// 子 env 下顺序求值，最后一个表达式在尾位置。
+		"let": func(args []Expr, env *Env) (Val, error) {
+			if len(args) < 2 {
+				return nil, fmt.Errorf("let takes at least 2 args")
+			}
+			bindings, ok := unwrap(args[0]).(List)
+			if !ok {
+				return nil, fmt.Errorf("let bindings must be a list")
+			}
+			newEnv := NewEnv(env)
+			for _, b := range bindings {
+				name, val, err := evalBinding(b, env)
+				if err != nil {
+					return nil, err
+				}
+				newEnv.Set(name, val)
+			}
+			return evalBody(args[1:], newEnv)
+		},
+		// let* 的绑定按顺序求值，后面的绑定能看见前面已经绑好的名字。
+		"let*": func(args []Expr, env *Env) (Val, error) {
+			if len(args) < 2 {
+				return nil, fmt.Errorf("let* takes at least 2 args")
+			}
+			bindings, ok := unwrap(args[0]).(List)
+			if !ok {
+				return nil, fmt.Errorf("let* bindings must be a list")
+			}
+			newEnv := NewEnv(env)
+			for _, b := range bindings {
+				name, val, err := evalBinding(b, newEnv)
+				if err != nil {
+					return nil, err
+				}
+				newEnv.Set(name, val)
+			}
+			return evalBody(args[1:], newEnv)
+		},
+		// letrec 先把所有名字占位绑到同一个新 env，再按顺序求值绑定表达式——
+		// 这样互相递归的 lambda（比如 even?/odd?）在 body 被调用时都已经能
+		// 看到彼此，只要不在绑定表达式求值的那一刻就去读还没轮到的名字。
+		"letrec": func(args []Expr, env *Env) (Val, error) {
+			if len(args) < 2 {
+				return nil, fmt.Errorf("letrec takes at least 2 args")
+			}
+			bindings, ok := unwrap(args[0]).(List)
+			if !ok {
+				return nil, fmt.Errorf("letrec bindings must be a list")
+			}
+			newEnv := NewEnv(env)
+			names := make([]string, len(bindings))
+			exprs := make([]Expr, len(bindings))
+			for i, b := range bindings {
+				pair, ok := unwrap(b).(List)
+				if !ok || len(pair) != 2 {
+					return nil, fmt.Errorf("letrec binding must be (name expr)")
+				}
+				sym, ok := unwrap(pair[0]).(Symbol)
+				if !ok {
+					return nil, fmt.Errorf("letrec binding name must be symbol")
+				}
+				names[i] = string(sym)
+				exprs[i] = pair[1]
+				newEnv.Set(names[i], Bool(false)) // 占位，下面按顺序立刻覆盖成真正的值
+			}
+			for i, e := range exprs {
+				val, err := e.Eval(newEnv)
+				if err != nil {
+					return nil, err
+				}
+				newEnv.Set(names[i], val)
+			}
+			return evalBody(args[1:], newEnv)
+		},
+		// cond 依次测试每个子句，命中的子句里最后一个表达式在尾位置；
+		// else 子句永远命中。没有子句命中时返回 nil（unspecified）。
+		"cond": func(args []Expr, env *Env) (Val, error) {
+			for _, clauseExpr := range args {
+				clause, ok := unwrap(clauseExpr).(List)
+				if !ok || len(clause) == 0 {
+					return nil, fmt.Errorf("cond clause must be a non-empty list")
+				}
+				test := Val(Bool(true))
+				if sym, ok := unwrap(clause[0]).(Symbol); !ok || sym != "else" {
+					v, err := clause[0].Eval(env)
+					if err != nil {
+						return nil, err
+					}
+					test = v
+				}
+				if isFalse(test) {
+					continue
+				}
+				if len(clause) == 1 {
+					return test, nil
+				}
+				return evalBody(clause[1:], env)
+			}
+			return nil, nil
+		},
+		// set! 原地修改已有绑定，而不是像 define 那样总在当前帧新建。
+		"set!": func(args []Expr, env *Env) (Val, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("set! takes 2 args")
+			}
+			sym, ok := unwrap(args[0]).(Symbol)
+			if !ok {
+				return nil, fmt.Errorf("set! first arg must be symbol")
+			}
+			val, err := args[1].Eval(env)
+			if err != nil {
+				return nil, err
+			}
+			if !env.SetMutate(string(sym), val) {
+				return nil, fmt.Errorf("set!: undefined symbol: %s", sym)
+			}
+			return nil, nil
+		},
+		"call/cc":                        callCC,
+		"call-with-current-continuation": callCC,
+		// dynamic-wind 在求值 thunk 前后分别跑 before/after。这里没有手动
+		// 维护一份额外的"动态范围栈"——Go 的 defer 本身已经跟着调用栈天然
+		// 反映了 extent 的嵌套：即使 thunk 内部通过 continuation 的 panic
+		// 发生了跨越 extent 的非局部跳转，展开经过这一帧时 defer 照样会
+		// 把 after 跑掉。
+		"dynamic-wind": func(args []Expr, env *Env) (result Val, err error) {
+			if len(args) != 3 {
+				return nil, fmt.Errorf("dynamic-wind takes 3 args")
+			}
+			beforeVal, err := args[0].Eval(env)
+			if err != nil {
+				return nil, err
+			}
+			thunkVal, err := args[1].Eval(env)
+			if err != nil {
+				return nil, err
+			}
+			afterVal, err := args[2].Eval(env)
+			if err != nil {
+				return nil, err
+			}
+			if _, err = applyVal(beforeVal, nil); err != nil {
+				return nil, err
+			}
+			defer func() {
+				if _, afterErr := applyVal(afterVal, nil); afterErr != nil && err == nil {
+					err = afterErr
+				}
+			}()
+			return applyVal(thunkVal, nil)
+		},
+		// quote 把未求值的语法节点原样转成 quote 数据（见 exprToData），
+		// List 变成 Pair 链，原子自求值。
+		"quote": func(args []Expr, env *Env) (Val, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("quote takes 1 arg")
+			}
+			return exprToData(args[0])
+		},
+		"quasiquote": func(args []Expr, env *Env) (Val, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("quasiquote takes 1 arg")
+			}
+			return evalQuasiquote(args[0], env)
+		},
+		// unquote/unquote-splicing 只在 quasiquote 内部有意义，
+		// evalQuasiquote 在递归展开时会直接识别并求值它们，不会把它们
+		// 当普通 special form 派发到这里——落到这里说明是在 quasiquote
+		// 之外裸用，是个错误。
+		"unquote": func(args []Expr, env *Env) (Val, error) {
+			return nil, fmt.Errorf("unquote: not inside a quasiquote")
+		},
+		"unquote-splicing": func(args []Expr, env *Env) (Val, error) {
+			return nil, fmt.Errorf("unquote-splicing: not inside a quasiquote")
+		},
+	}
+}
+
+// evalQuasiquote 展开一层 quasiquote：普通节点原样转换成 quote 数据，
+// 遇到 (unquote x) 就对 x 求值替换进去，列表里的 (unquote-splicing x)
+// 对 x 求值（必须是个 proper list）后把它的元素拼接展开到当前位置。为了
+// 保持实现简单，这里不追踪嵌套 quasiquote 的深度——嵌套的 unquote 会在
+// 离它最近的这层 quasiquote 就被求值，这和大多数玩具实现一致，但和
+// R7RS 对嵌套 quasiquote/unquote 深度匹配的精确语义不完全相同。
+func evalQuasiquote(e Expr, env *Env) (Val, error) {
+	if l, ok := unwrap(e).(List); ok {
+		if len(l) == 2 {
+			if sym, ok := unwrap(l[0]).(Symbol); ok && sym == "unquote" {
+				return l[1].Eval(env)
+			}
 		}
-		val, err := args[1].Eval(env)
+		items, err := evalQuasiquoteItems(l, env)
 		if err != nil {
 			return nil, err
 		}
-		env.Set(string(sym), val)
-		return nil, nil // define 返回 nil
-	},
-	"if": func(args []Expr, env *Env) (Val, error) {
-		if len(args) != 3 {
-			return nil, fmt.Errorf("if takes 3 args")
-		}
-		cond, err := args[0].Eval(env)
+		return sliceToPairs(items), nil
+	}
+	if dl, ok := unwrap(e).(DottedList); ok {
+		headItems, err := evalQuasiquoteItems(dl.Items, env)
 		if err != nil {
 			return nil, err
 		}
-		// 只有 #f 是 false，其他都是 true
-		if b, ok := cond.(Bool); ok && !bool(b) {
-			return args[2].Eval(env)
-		}
-		return args[1].Eval(env)
-	},
-	"lambda": func(args []Expr, env *Env) (Val, error) {
-		if len(args) != 2 {
-			return nil, fmt.Errorf("lambda takes 2 args")
-		}
-		paramsNode, ok := args[0].(List)
-		if !ok {
-			return nil, fmt.Errorf("lambda params must be a list")
-		}
-		params := make([]string, len(paramsNode))
-		for i, p := range paramsNode {
-			sym, ok := p.(Symbol)
-			if !ok {
-				return nil, fmt.Errorf("param must be symbol")
-			}
-			params[i] = string(sym)
-		}
-		return Lambda{params: params, body: args[1], env: env}, nil
-	},
-	"and": func(args []Expr, env *Env) (Val, error) {
-		if len(args) != 2 {
-			return nil, fmt.Errorf("and takes 2 args")
-		}
-		cond1, err := args[0].Eval(env)
+		tail, err := evalQuasiquote(dl.Tail, env)
 		if err != nil {
 			return nil, err
 		}
-		if cond1b, ok := cond1.(Bool); ok {
-			if !cond1b {
-				return cond1, nil
-			}
-			cond2, err := args[1].Eval(env)
-			if err != nil {
-				return nil, err
-			}
-			if cond2b, ok := cond2.(Bool); ok {
-				return cond2b, nil
-			}
-			return nil, fmt.Errorf("and arg must be a bool")
+		result := tail
+		for i := len(headItems) - 1; i >= 0; i-- {
+			result = Pair{Car: headItems[i], Cdr: result}
 		}
-		return nil, fmt.Errorf("and arg must be a bool")
-	},
-	"or": func(args []Expr, env *Env) (Val, error) {
-		if len(args) != 2 {
-			return nil, fmt.Errorf("or takes 2 args")
+		return result, nil
+	}
+	return exprToData(e)
+}
+
+// evalQuasiquoteItems 按顺序展开 list 里的每个元素，(unquote-splicing x)
+// 把 x 的元素整个拼接进结果，其余元素各自递归展开成一个元素。返回摊平
+// 的 Go slice（而不是 Pair 链），调用方按自己的需要再决定怎么收尾
+// （拼成 proper list 还是接在一个 dotted tail 前面）。
+func evalQuasiquoteItems(l List, env *Env) ([]Val, error) {
+	var items []Val
+	for _, e := range l {
+		if sub, ok := unwrap(e).(List); ok && len(sub) == 2 {
+			if sym, ok := unwrap(sub[0]).(Symbol); ok && sym == "unquote-splicing" {
+				spliced, err := sub[1].Eval(env)
+				if err != nil {
+					return nil, err
+				}
+				vals, err := pairToSlice(spliced)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, vals...)
+				continue
+			}
 		}
-		cond1, err := args[0].Eval(env)
+		v, err := evalQuasiquote(e, env)
 		if err != nil {
 			return nil, err
 		}
-		if cond1b, ok := cond1.(Bool); ok {
-			if cond1b {
-				return cond1, nil
-			}
-			cond2, err := args[1].Eval(env)
-			if err != nil {
-				return nil, err
-			}
-			if cond2b, ok := cond2.(Bool); ok {
-				return cond2b, nil
-			}
-			return nil, fmt.Errorf("or arg must be a bool")
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+// evalBody 顺序求值一串 body 表达式，只有最后一个处在尾位置，供
+// begin/let/let*/letrec/cond 共用。
+func evalBody(body []Expr, env *Env) (Val, error) {
+	if len(body) == 0 {
+		return nil, fmt.Errorf("body requires at least 1 expr")
+	}
+	for _, e := range body[:len(body)-1] {
+		if _, err := e.Eval(env); err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("or arg must be a bool")
-	},
+	}
+	return evalTail(body[len(body)-1], env)
+}
+
+// evalBinding 解析并求值一个 (name expr) 形式的 let/let* 绑定，expr 在
+// bindEnv 下求值——let 传外层 env，let* 传正在累积的新 env。
+func evalBinding(b Expr, bindEnv *Env) (string, Val, error) {
+	pair, ok := unwrap(b).(List)
+	if !ok || len(pair) != 2 {
+		return "", nil, fmt.Errorf("let binding must be (name expr)")
+	}
+	sym, ok := unwrap(pair[0]).(Symbol)
+	if !ok {
+		return "", nil, fmt.Errorf("let binding name must be symbol")
+	}
+	val, err := pair[1].Eval(bindEnv)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(sym), val, nil
 }
 
-// 辅助函数：将 Number 转换逻辑抽离
-func assertNums(args []Val) ([]float64, error) {
-	nums := make([]float64, len(args))
+// 辅助函数：把参数列表转换成 Num，供算术/比较 builtin 复用
+func assertNums(args []Val) ([]Num, error) {
+	nums := make([]Num, len(args))
 	for i, arg := range args {
-		n, ok := arg.(Number)
+		n, ok := arg.(Num)
 		if !ok {
 			return nil, fmt.Errorf("expected number, got %T", arg)
 		}
-		nums[i] = float64(n)
+		nums[i] = n
 	}
 	return nums, nil
 }
 
 // 辅助函数：创建二元数值操作符 (减少重复代码)
-func binaryNumOp(op func(a, b float64) float64) Builtin {
+func binaryNumOp(op func(a, b Num) Num) Builtin {
 	return func(args []Val) (Val, error) {
 		nums, err := assertNums(args)
 		if err != nil {
@@ -271,12 +1092,12 @@ func binaryNumOp(op func(a, b float64) float64) Builtin {
 		for _, n := range nums[1:] {
 			res = op(res, n)
 		}
-		return Number(res), nil
+		return res, nil
 	}
 }
 
-// 辅助函数：创建比较操作符
-func compareOp(op func(a, b float64) bool) Builtin {
+// 辅助函数：创建比较操作符。两数之差的符号就决定了 = < > 的结果。
+func compareOp(op func(sign int) bool) Builtin {
 	return func(args []Val) (Val, error) {
 		if len(args) != 2 {
 			return nil, fmt.Errorf("comparison requires 2 args")
@@ -285,20 +1106,44 @@ func compareOp(op func(a, b float64) bool) Builtin {
 		if err != nil {
 			return nil, err
 		}
-		return Bool(op(nums[0], nums[1])), nil
+		return Bool(op(nums[0].sub(nums[1]).sign())), nil
 	}
 }
 
+func assertString(v Val) (String, error) {
+	s, ok := v.(String)
+	if !ok {
+		return "", fmt.Errorf("expected string, got %T", v)
+	}
+	return s, nil
+}
+
+func assertInt(v Val) (Int, error) {
+	i, ok := v.(Int)
+	if !ok {
+		return 0, fmt.Errorf("expected integer, got %T", v)
+	}
+	return i, nil
+}
+
+func assertVector(v Val) (Vector, error) {
+	vec, ok := v.(Vector)
+	if !ok {
+		return Vector{}, fmt.Errorf("expected vector, got %T", v)
+	}
+	return vec, nil
+}
+
 func loadBuiltins(env *Env) {
-	env.Set("+", binaryNumOp(func(a, b float64) float64 { return a + b }))
-	env.Set("-", binaryNumOp(func(a, b float64) float64 { return a - b }))
-	env.Set("*", binaryNumOp(func(a, b float64) float64 { return a * b }))
-	env.Set("/", binaryNumOp(func(a, b float64) float64 { return a / b }))
-	env.Set("=", compareOp(func(a, b float64) bool { return a == b }))
-	env.Set("<", compareOp(func(a, b float64) bool { return a < b }))
-	env.Set("<=", compareOp(func(a, b float64) bool { return a <= b }))
-	env.Set(">=", compareOp(func(a, b float64) bool { return a >= b }))
-	env.Set(">", compareOp(func(a, b float64) bool { return a > b }))
+	env.Set("+", binaryNumOp(func(a, b Num) Num { return a.add(b) }))
+	env.Set("-", binaryNumOp(func(a, b Num) Num { return a.sub(b) }))
+	env.Set("*", binaryNumOp(func(a, b Num) Num { return a.mul(b) }))
+	env.Set("/", binaryNumOp(func(a, b Num) Num { return a.div(b) }))
+	env.Set("=", compareOp(func(s int) bool { return s == 0 }))
+	env.Set("<", compareOp(func(s int) bool { return s < 0 }))
+	env.Set("<=", compareOp(func(s int) bool { return s <= 0 }))
+	env.Set(">=", compareOp(func(s int) bool { return s >= 0 }))
+	env.Set(">", compareOp(func(s int) bool { return s > 0 }))
 	env.Set("not", Builtin(func(args []Val) (Val, error) {
 		if len(args) != 1 {
 			return nil, fmt.Errorf("not takes 1 args")
@@ -308,63 +1153,313 @@ func loadBuiltins(env *Env) {
 		}
 		return nil, fmt.Errorf("not takes bool arg")
 	}))
-}
 
-// ==========================================
-// 4. 解析器 (Parser) - 使用 Tokenizer 改进
-// ==========================================
+	env.Set("string-length", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("string-length takes 1 args")
+		}
+		s, err := assertString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return Int(len(s)), nil
+	}))
+	env.Set("string-append", Builtin(func(args []Val) (Val, error) {
+		var sb strings.Builder
+		for _, arg := range args {
+			s, err := assertString(arg)
+			if err != nil {
+				return nil, err
+			}
+			sb.WriteString(string(s))
+		}
+		return String(sb.String()), nil
+	}))
+	env.Set("substring", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 3 {
+			return nil, fmt.Errorf("substring takes 3 args")
+		}
+		s, err := assertString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		start, err := assertInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		end, err := assertInt(args[2])
+		if err != nil {
+			return nil, err
+		}
+		if start < 0 || end > Int(len(s)) || start > end {
+			return nil, fmt.Errorf("substring: index out of range")
+		}
+		return String(s[start:end]), nil
+	}))
+	env.Set("string->symbol", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("string->symbol takes 1 args")
+		}
+		s, err := assertString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return Symbol(s), nil
+	}))
 
-func readExpr(code string) (Expr, string, error) {
-	code = strings.TrimSpace(code)
+	env.Set("make-vector", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 1 && len(args) != 2 {
+			return nil, fmt.Errorf("make-vector takes 1 or 2 args")
+		}
+		n, err := assertInt(args[0])
+		if err != nil {
+			return nil, err
+		}
+		var fill Val = Bool(false)
+		if len(args) == 2 {
+			fill = args[1]
+		}
+		items := make([]Val, n)
+		for i := range items {
+			items[i] = fill
+		}
+		return Vector{items: items}, nil
+	}))
+	env.Set("vector-ref", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("vector-ref takes 2 args")
+		}
+		v, err := assertVector(args[0])
+		if err != nil {
+			return nil, err
+		}
+		i, err := assertInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || int(i) >= len(v.items) {
+			return nil, fmt.Errorf("vector-ref: index out of range")
+		}
+		return v.items[i], nil
+	}))
+	env.Set("vector-set!", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 3 {
+			return nil, fmt.Errorf("vector-set! takes 3 args")
+		}
+		v, err := assertVector(args[0])
+		if err != nil {
+			return nil, err
+		}
+		i, err := assertInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || int(i) >= len(v.items) {
+			return nil, fmt.Errorf("vector-set!: index out of range")
+		}
+		v.items[i] = args[2] // 共享底层数组，写回对调用方可见
+		return nil, nil
+	}))
+	env.Set("vector-length", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("vector-length takes 1 args")
+		}
+		v, err := assertVector(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return Int(len(v.items)), nil
+	}))
 
-	if len(code) == 0 {
-		return nil, "", errors.New("unexpected EOF")
-	}
+	env.Set("force", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("force takes 1 args")
+		}
+		switch v := args[0].(type) {
+		case *Future:
+			<-v.done
+			return v.result, v.err
+		case *Promise:
+			v.once.Do(func() {
+				v.result, v.err = v.expr.Eval(v.env)
+			})
+			return v.result, v.err
+		default:
+			return nil, fmt.Errorf("force: expected future or promise, got %T", args[0])
+		}
+	}))
+	env.Set("promise?", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("promise? takes 1 args")
+		}
+		_, ok := args[0].(*Promise)
+		return Bool(ok), nil
+	}))
+	// parallel-map 在 vector 的每个元素上并发调用 fn，按下标收集结果；
+	// 等 Pair/cons 落地后可以再加一个基于链表的版本。
+	env.Set("parallel-map", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("parallel-map takes 2 args")
+		}
+		fn := args[0]
+		vec, err := assertVector(args[1])
+		if err != nil {
+			return nil, err
+		}
+		results := make([]Val, len(vec.items))
+		errs := make([]error, len(vec.items))
+		var wg sync.WaitGroup
+		for i, item := range vec.items {
+			i, item := i, item
+			wg.Add(1)
+			workerPool.submit(func() {
+				defer wg.Done()
+				results[i], errs[i] = applyVal(fn, []Val{item})
+			})
+		}
+		wg.Wait()
+		for _, e := range errs {
+			if e != nil {
+				return nil, e
+			}
+		}
+		return Vector{items: results}, nil
+	}))
 
-	first := code[0]
-	if first == '(' {
-		remain := code[1:]
-		list := List{}
-		for {
-			remain = strings.TrimSpace(remain)
-			if len(remain) == 0 {
-				return nil, "", errors.New("unbalanced parenthesis: missing ')'")
+	env.Set("cons", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("cons takes 2 args")
+		}
+		return Pair{Car: args[0], Cdr: args[1]}, nil
+	}))
+	env.Set("car", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("car takes 1 args")
+		}
+		p, ok := args[0].(Pair)
+		if !ok {
+			return nil, fmt.Errorf("car: expected pair, got %T", args[0])
+		}
+		return p.Car, nil
+	}))
+	env.Set("cdr", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("cdr takes 1 args")
+		}
+		p, ok := args[0].(Pair)
+		if !ok {
+			return nil, fmt.Errorf("cdr: expected pair, got %T", args[0])
+		}
+		return p.Cdr, nil
+	}))
+	env.Set("pair?", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("pair? takes 1 args")
+		}
+		_, ok := args[0].(Pair)
+		return Bool(ok), nil
+	}))
+	env.Set("null?", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("null? takes 1 args")
+		}
+		_, ok := args[0].(nilType)
+		return Bool(ok), nil
+	}))
+	env.Set("list", Builtin(func(args []Val) (Val, error) {
+		return sliceToPairs(args), nil
+	}))
+	env.Set("length", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("length takes 1 args")
+		}
+		items, err := pairToSlice(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return Int(len(items)), nil
+	}))
+	env.Set("append", Builtin(func(args []Val) (Val, error) {
+		var all []Val
+		for _, a := range args {
+			items, err := pairToSlice(a)
+			if err != nil {
+				return nil, err
 			}
-			if remain[0] == ')' {
-				return list, remain[1:], nil
+			all = append(all, items...)
+		}
+		return sliceToPairs(all), nil
+	}))
+	env.Set("reverse", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("reverse takes 1 args")
+		}
+		items, err := pairToSlice(args[0])
+		if err != nil {
+			return nil, err
+		}
+		reversed := make([]Val, len(items))
+		for i, v := range items {
+			reversed[len(items)-1-i] = v
+		}
+		return sliceToPairs(reversed), nil
+	}))
+	env.Set("map", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("map takes 2 args")
+		}
+		items, err := pairToSlice(args[1])
+		if err != nil {
+			return nil, err
+		}
+		results := make([]Val, len(items))
+		for i, item := range items {
+			v, err := applyVal(args[0], []Val{item})
+			if err != nil {
+				return nil, err
 			}
-			expr, remainNext, err := readExpr(remain)
+			results[i] = v
+		}
+		return sliceToPairs(results), nil
+	}))
+	env.Set("filter", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("filter takes 2 args")
+		}
+		items, err := pairToSlice(args[1])
+		if err != nil {
+			return nil, err
+		}
+		var results []Val
+		for _, item := range items {
+			v, err := applyVal(args[0], []Val{item})
 			if err != nil {
-				return nil, "", err
+				return nil, err
+			}
+			if !isFalse(v) {
+				results = append(results, item)
 			}
-			list = append(list, expr)
-			remain = remainNext
 		}
-	}
-
-	nextParamsIdx := strings.IndexFunc(code, func(r rune) bool {
-		return unicode.IsSpace(r) || r == ')' || r == '('
-	})
-
-	var token string
-	var nextRemain string
-
-	if nextParamsIdx == -1 {
-		token = code
-		nextRemain = ""
-	} else {
-		token = code[:nextParamsIdx]
-		nextRemain = code[nextParamsIdx:]
-	}
-	if token == "#t" {
-		return Bool(true), nextRemain, nil
-	} else if token == "#f" {
-		return Bool(false), nextRemain, nil
-	} else if num, err := strconv.ParseFloat(token, 64); err == nil {
-		return Number(num), nextRemain, nil
-	} else {
-		return Symbol(token), nextRemain, nil
-	}
+		return sliceToPairs(results), nil
+	}))
+	// foldl 从左到右依次把 (proc acc item) 喂给 proc，acc 从 init 开始滚动。
+	env.Set("foldl", Builtin(func(args []Val) (Val, error) {
+		if len(args) != 3 {
+			return nil, fmt.Errorf("foldl takes 3 args")
+		}
+		items, err := pairToSlice(args[2])
+		if err != nil {
+			return nil, err
+		}
+		acc := args[1]
+		for _, item := range items {
+			acc, err = applyVal(args[0], []Val{acc, item})
+			if err != nil {
+				return nil, err
+			}
+		}
+		return acc, nil
+	}))
 }
 
 func fileMode(testFile string, env *Env) {
@@ -379,14 +1474,11 @@ func fileMode(testFile string, env *Env) {
 	code := string(bytes)
 	remain := strings.TrimSpace(code)
 
-	// 2. 正则去除注释 (; 到行尾)，对应 TS 的 .replace(/;.*$/gm, "")
-	// (?m) 开启多行模式
-	re := regexp.MustCompile(`(?m);.*$`)
-	remain = re.ReplaceAllString(remain, "")
-	remain = strings.TrimSpace(remain)
-
+	// 注释（; 到行尾、#| |#、#;）现在完全交给 scanner 处理，这里不再自己
+	// 正则去注释——之前那个 (?m);.*$ 正则不认识字符串/block comment，会把
+	// "foo;bar" 或 #| ...; ... |# 里的 ; 也当成注释起点切掉，切坏字符串
+	// 字面量和 block comment。
 	// fmt.Printf("Running file: %s\n", testFile)
-	// 3. 循环消费字符串
 
 	for len(strings.TrimSpace(remain)) > 0 {
 		var expr Expr
@@ -396,6 +1488,10 @@ func fileMode(testFile string, env *Env) {
 			fmt.Printf("Syntax Error: %v\n", err)
 			break
 		}
+		if expr == nil {
+			// 剩下的只是空白和注释，没有更多表达式了。
+			break
+		}
 
 		res, err := expr.Eval(env)
 
@@ -426,9 +1522,18 @@ func replMode(env *Env) {
 			fmt.Print("scheme> ")
 			continue
 		}
+		if expr == nil {
+			// 整行只有空白和注释，没有表达式要求值。
+			fmt.Print("scheme> ")
+			continue
+		}
 
 		if len(strings.TrimSpace(remain)) != 0 {
-			fmt.Println("Only one expression per line!")
+			// remain 里可能只剩一条行尾注释，不算第二条表达式，得拿
+			// readExpr 自己的注释处理再确认一遍，不能只看去空白是否为空。
+			if trailing, _, err := readExpr(remain); err != nil || trailing != nil {
+				fmt.Println("Only one expression per line!")
+			}
 		}
 
 		res, err := expr.Eval(env)
@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+// sumSquaresVector 构造一个 0..n-1 的 Vector，供并行/串行求和基准测试复用。
+func sumSquaresVector(n int) Vector {
+	items := make([]Val, n)
+	for i := range items {
+		items[i] = Int(i)
+	}
+	return Vector{items: items}
+}
+
+func sumVector(v Vector) Int {
+	var total Int
+	for _, it := range v.items {
+		total += it.(Int)
+	}
+	return total
+}
+
+// BenchmarkParallelSumOfSquares 通过 parallel-map 在 workerPool 上并发求平方
+// 后串行求和，和 BenchmarkSerialSumOfSquares 对比体现 future 池带来的加速。
+func BenchmarkParallelSumOfSquares(b *testing.B) {
+	env := NewEnv(nil)
+	loadBuiltins(env)
+	sq, _ := env.Get("parallel-map")
+	fn := Lambda{params: []string{"x"}, body: List{Symbol("*"), Symbol("x"), Symbol("x")}, env: env}
+	vec := sumSquaresVector(100000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		res, err := applyVal(sq, []Val{fn, vec})
+		if err != nil {
+			b.Fatal(err)
+		}
+		sumVector(res.(Vector))
+	}
+}
+
+func BenchmarkSerialSumOfSquares(b *testing.B) {
+	env := NewEnv(nil)
+	loadBuiltins(env)
+	fn := Lambda{params: []string{"x"}, body: List{Symbol("*"), Symbol("x"), Symbol("x")}, env: env}
+	vec := sumSquaresVector(100000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		results := make([]Val, len(vec.items))
+		for j, item := range vec.items {
+			res, err := applyVal(fn, []Val{item})
+			if err != nil {
+				b.Fatal(err)
+			}
+			results[j] = res
+		}
+		sumVector(Vector{items: results})
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import "testing"
+
+// TestCallCCEscapesDeepNonTailRecursion 让 descend 用非尾位置的
+// (+ 1 (descend ...)) 往下递归上万层真实 Go 栈帧，再从最底下调用捕获到
+// 的 continuation。如果逃逸没有真的跳过所有那些 "+1"，结果会比 999 大
+// 得多，而不是干净的 999。
+func TestCallCCEscapesDeepNonTailRecursion(t *testing.T) {
+	got := runProgram(t, `
+		(define count 0)
+		(define descend (lambda (n k)
+		  (if (= n 0)
+		      (k 999)
+		      (begin (set! count (+ count 1)) (+ 1 (descend (- n 1) k))))))
+		(call/cc (lambda (return) (descend 100000 return)))
+	`)
+	if got != Int(999) {
+		t.Fatalf("expected 999, got %v", got)
+	}
+}
+
+// TestCallCCEarlyExitFromTailTraversal 在一个跑 1e7 次的尾递归遍历里，
+// 提前从第 5 个元素处逃逸。靠 TCO 这个遍历本身不会长 Go 栈，call/cc 得
+// 在 trampoline 正在循环的时候正确地用 panic 跳出来，而不是傻等循环
+// 跑满全部 1e7 轮。
+func TestCallCCEarlyExitFromTailTraversal(t *testing.T) {
+	got := runProgram(t, `
+		(define walk (lambda (i limit k)
+		  (if (= i limit)
+		      -1
+		      (if (= i 5)
+		          (k i)
+		          (walk (+ i 1) limit k)))))
+		(call/cc (lambda (return) (walk 0 10000000 return)))
+	`)
+	if got != Int(5) {
+		t.Fatalf("expected 5, got %v", got)
+	}
+}
+
+// TestCallCCFindFirstInVector 用 call/cc 实现 map 风格遍历里常见的
+// "找到第一个满足条件的元素就提前退出"，覆盖 vector 遍历 + 逃逸的组合。
+func TestCallCCFindFirstInVector(t *testing.T) {
+	got := runProgram(t, `
+		(define find-first (lambda (vec pred)
+		  (call/cc (lambda (return)
+		    (letrec ((walk (lambda (i)
+		                (if (= i (vector-length vec))
+		                    -1
+		                    (if (pred (vector-ref vec i))
+		                        (return i)
+		                        (walk (+ i 1)))))))
+		      (walk 0))))))
+		(find-first #(10 20 30 40 50) (lambda (x) (= x 30)))
+	`)
+	if got != Int(2) {
+		t.Fatalf("expected 2, got %v", got)
+	}
+}
+
+// TestDynamicWindFiresAcrossEscape 确认 before/after 即使在 thunk 内部
+// 通过 continuation 提前跳出时也会各跑一次，且顺序正确。
+func TestDynamicWindFiresAcrossEscape(t *testing.T) {
+	got := runProgram(t, `
+		(define log "")
+		(call/cc (lambda (return)
+		  (dynamic-wind
+		    (lambda () (set! log (string-append log "before,")))
+		    (lambda () (begin
+		                 (set! log (string-append log "thunk,"))
+		                 (return 1)
+		                 (set! log (string-append log "unreachable,"))))
+		    (lambda () (set! log (string-append log "after,"))))))
+		log
+	`)
+	if got != String("before,thunk,after,") {
+		t.Fatalf("expected %q, got %v", "before,thunk,after,", got)
+	}
+}
+
+// TestCallCCInvokedAfterReturnErrors 一旦捕获 continuation 的 call/cc 已经
+// 正常返回，它的动态范围就结束了。这是一次性的逃逸续延，不是完整的多次
+// 调用续延，所以之后再调用同一个 k 必须是一个 Scheme 层的错误，而不是
+// 让整个解释器 panic 崩掉。
+func TestCallCCInvokedAfterReturnErrors(t *testing.T) {
+	env := NewEnv(nil)
+	loadBuiltins(env)
+
+	for _, src := range []string{
+		`(define saved #f)`,
+		`(call/cc (lambda (k) (begin (set! saved k) 0)))`,
+	} {
+		expr, _, err := readExpr(src)
+		if err != nil {
+			t.Fatalf("parse error: %v", err)
+		}
+		if _, err := expr.Eval(env); err != nil {
+			t.Fatalf("eval error: %v", err)
+		}
+	}
+
+	expr, _, err := readExpr(`(saved 5)`)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if _, err := expr.Eval(env); err == nil {
+		t.Fatal("expected invoking an expired continuation to error, got nil")
+	}
+}
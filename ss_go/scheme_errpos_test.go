@@ -0,0 +1,26 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestErrorPositionWrappedOnce 确认嵌套表达式出错时只带一个 "Error at"
+// 前缀——取最靠近出错位置的那一层，而不是每往外冒泡经过一层 Node 就再叠
+// 一层前缀。
+func TestErrorPositionWrappedOnce(t *testing.T) {
+	env := NewEnv(nil)
+	loadBuiltins(env)
+
+	expr, _, err := readExpr(`(+ 1 (+ 2 (+ 3 undefined-var)))`)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	_, evalErr := expr.Eval(env)
+	if evalErr == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if n := strings.Count(evalErr.Error(), "Error at"); n != 1 {
+		t.Fatalf("expected exactly one \"Error at\" prefix, got %d: %v", n, evalErr)
+	}
+}
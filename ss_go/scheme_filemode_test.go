@@ -0,0 +1,80 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// captureFileModeOutput 把 fileMode 打印到 stdout 的内容捕获成字符串，
+// 供下面这条回归测试断言输出，而不用改 fileMode 本身的签名。
+func captureFileModeOutput(t *testing.T, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "prog.scm")
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	old := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	os.Stdout = w
+
+	env := NewEnv(nil)
+	loadBuiltins(env)
+	fileMode(path, env)
+
+	w.Close()
+	os.Stdout = old
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+// TestFileModeSemicolonInsideStringAndBlockComment 是 chunk0-3 本该统一
+// 到 scanner 的注释处理，file 模式之前自己用正则 (?m);.*$ 去注释，不认识
+// 字符串字面量和 #| |# block comment，把里面的 ; 也当成注释起点切掉。
+func TestFileModeSemicolonInsideStringAndBlockComment(t *testing.T) {
+	out := captureFileModeOutput(t, `"foo;bar"`)
+	if out != "\"foo;bar\"\n" {
+		t.Fatalf("expected %q, got %q", "\"foo;bar\"\n", out)
+	}
+
+	out = captureFileModeOutput(t, "#| a comment; with semicolon |# \"ok\"")
+	if out != "\"ok\"\n" {
+		t.Fatalf("expected %q, got %q", "\"ok\"\n", out)
+	}
+}
+
+// TestFileModeCommentOnlyFileProducesNoOutput 一个只有注释、没有任何
+// 表达式的文件应该安静地跑完，而不是在 scanner 跳过注释、吃到 EOF 之后
+// 报一句 "Syntax Error: unexpected EOF"。
+func TestFileModeCommentOnlyFileProducesNoOutput(t *testing.T) {
+	out := captureFileModeOutput(t, "; just a comment\n")
+	if out != "" {
+		t.Fatalf("expected no output, got %q", out)
+	}
+}
+
+// TestReadExprTrailingCommentLeavesNilRemainder replMode 靠"读完一个
+// expr 之后 remain 是不是还有东西"来判断一行里是不是塞了多条表达式。如果
+// remain 只是行尾的一条注释，它本身再喂给 readExpr 应该得到 (nil, "",
+// nil)，而不是被当成还有一条表达式没读。
+func TestReadExprTrailingCommentLeavesNilRemainder(t *testing.T) {
+	expr, remain, err := readExpr(`(+ 1 2) ; trailing comment`)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if expr == nil {
+		t.Fatal("expected an expression, got nil")
+	}
+	trailing, _, err := readExpr(remain)
+	if err != nil {
+		t.Fatalf("parse error on remainder: %v", err)
+	}
+	if trailing != nil {
+		t.Fatalf("expected nil after a trailing comment, got %v", trailing)
+	}
+}
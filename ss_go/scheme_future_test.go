@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+// TestNestedFutureForceDoesNotDeadlock 用递归 psum 模拟 parallel-sum 式
+// 分治：每一层都 (force (future (psum ...))) 等嵌套的 future 算完。固定
+// 大小的 workerPool 会让所有 worker 都阻塞在 force 上互相等待而死锁；
+// future 独立开 goroutine 之后不应该卡住。
+func TestNestedFutureForceDoesNotDeadlock(t *testing.T) {
+	got := runProgram(t, `
+		(define psum (lambda (n)
+		  (if (= n 0)
+		      0
+		      (+ n (force (future (psum (- n 1))))))))
+		(psum 2000)
+	`)
+	if got != Int(2001000) {
+		t.Fatalf("expected 2001000, got %v", got)
+	}
+}
+
+// TestContinuationEscapeFromFutureErrors call/cc 不能跨 goroutine 逃逸：
+// 在 (future ...) body 里调用外层捕获的 continuation 必须变成一个
+// Scheme 层的错误（通过 force 的返回值传出来），而不是让 panic 跑出
+// goroutine 直接崩掉整个解释器进程。
+func TestContinuationEscapeFromFutureErrors(t *testing.T) {
+	env := NewEnv(nil)
+	loadBuiltins(env)
+
+	expr, _, err := readExpr(`(call/cc (lambda (k) (force (future (k 42)))))`)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if _, err := expr.Eval(env); err == nil {
+		t.Fatal("expected invoking a continuation from inside a future to error, got nil")
+	}
+}
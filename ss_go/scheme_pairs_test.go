@@ -0,0 +1,91 @@
+package main
+
+import "testing"
+
+// TestQuoteBuildsPairChain 确认 quote 把解析出的 List 转成真正的 Pair
+// 链，而不是留着 parse-time 的 List AST，car/cdr/pair? 能正确操作它。
+func TestQuoteBuildsPairChain(t *testing.T) {
+	got := runProgram(t, `(car (cdr (quote (1 2 3))))`)
+	if got != Int(2) {
+		t.Fatalf("expected 2, got %v", got)
+	}
+}
+
+// TestQuoteDottedPair 确认 '(1 . 2) 这样的点对语法被转换成一个 Cdr 不是
+// Nil 的 improper Pair，而不是报错或者被截断成 proper list。
+func TestQuoteDottedPair(t *testing.T) {
+	got := runProgram(t, `(cdr (quote (1 . 2)))`)
+	if got != Int(2) {
+		t.Fatalf("expected 2, got %v", got)
+	}
+	if runProgram(t, `(pair? (quote (1 . 2)))`) != Bool(true) {
+		t.Fatal("expected (1 . 2) to be a pair")
+	}
+}
+
+// TestListBuiltinsRoundTrip 覆盖 list/length/append/reverse/map/filter/
+// foldl 这组基于 Pair 链的 builtin。
+func TestListBuiltinsRoundTrip(t *testing.T) {
+	cases := []struct {
+		src  string
+		want Val
+	}{
+		{`(length (list 1 2 3 4))`, Int(4)},
+		{`(null? (list))`, Bool(true)},
+		{`(pair? (list 1))`, Bool(true)},
+		{`(foldl (lambda (acc x) (+ acc x)) 0 (map (lambda (x) (* x x)) (list 1 2 3)))`, Int(14)},
+	}
+	for _, c := range cases {
+		if got := runProgram(t, c.src); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.src, c.want, got)
+		}
+	}
+
+	got := runProgram(t, `(filter (lambda (x) (> x 2)) (list 1 2 3 4))`)
+	want := runProgram(t, `(list 3 4)`)
+	if got.String() != want.String() {
+		t.Errorf("filter: expected %v, got %v", want, got)
+	}
+
+	got = runProgram(t, `(append (list 1 2) (list 3 4))`)
+	want = runProgram(t, `(list 1 2 3 4)`)
+	if got.String() != want.String() {
+		t.Errorf("append: expected %v, got %v", want, got)
+	}
+
+	got = runProgram(t, `(reverse (list 1 2 3))`)
+	want = runProgram(t, `(list 3 2 1)`)
+	if got.String() != want.String() {
+		t.Errorf("reverse: expected %v, got %v", want, got)
+	}
+}
+
+// TestQuasiquoteUnquote 覆盖 quasiquote 的普通替换和 unquote-splicing，
+// 以及点对尾部里的 unquote。
+func TestQuasiquoteUnquote(t *testing.T) {
+	got := runProgram(t, "`(1 2 ,(+ 1 2))")
+	want := runProgram(t, `(list 1 2 3)`)
+	if got.String() != want.String() {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	got = runProgram(t, "`(1 ,@(list 2 3) 4)")
+	want = runProgram(t, `(list 1 2 3 4)`)
+	if got.String() != want.String() {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	got = runProgram(t, "`(a . ,(+ 1 1))")
+	if got.String() != "(a . 2)" {
+		t.Errorf("expected (a . 2), got %v", got)
+	}
+}
+
+// TestVectorLiteralWithNestedList 确认 #(...) 字面量里可以嵌套一个待
+// quote 的子列表，会被转换成 Pair 链而不是报错。
+func TestVectorLiteralWithNestedList(t *testing.T) {
+	got := runProgram(t, `(car (vector-ref #(1 2 (3 4)) 2))`)
+	if got != Int(3) {
+		t.Fatalf("expected 3, got %v", got)
+	}
+}
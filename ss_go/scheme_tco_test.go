@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// runProgram 依次解析并求值 src 里的每个顶层表达式，返回最后一个的结果，
+// 复用 fileMode 的"解析一个、吃掉剩余源码"循环结构。
+func runProgram(t *testing.T, src string) Val {
+	t.Helper()
+	env := NewEnv(nil)
+	loadBuiltins(env)
+
+	remain := src
+	var result Val
+	for len(strings.TrimSpace(remain)) > 0 {
+		var expr Expr
+		var err error
+		expr, remain, err = readExpr(remain)
+		if err != nil {
+			t.Fatalf("parse error: %v", err)
+		}
+		if expr == nil {
+			break
+		}
+		result, err = expr.Eval(env)
+		if err != nil {
+			t.Fatalf("eval error: %v", err)
+		}
+	}
+	return result
+}
+
+// TestTailCallDeepRecursion 驱动一个 1e7 层深的尾递归 loop：没有 trampoline
+// 的话这个调用会直接撑爆 Go 调用栈而不是正常返回。
+func TestTailCallDeepRecursion(t *testing.T) {
+	got := runProgram(t, `
+		(define loop (lambda (n) (if (= n 0) 42 (loop (- n 1)))))
+		(loop 10000000)
+	`)
+	if got != Int(42) {
+		t.Fatalf("expected 42, got %v", got)
+	}
+}
+
+// TestLetrecMutualTailRecursion 用 letrec 定义互相尾递归的 even?/odd?，
+// 确认 TCO 对跨函数（而不只是自身）的尾调用同样生效。
+func TestLetrecMutualTailRecursion(t *testing.T) {
+	got := runProgram(t, `
+		(letrec ((even? (lambda (n) (if (= n 0) 1 (odd? (- n 1)))))
+		         (odd? (lambda (n) (if (= n 0) 0 (even? (- n 1))))))
+		  (even? 1000000))
+	`)
+	if got != Int(1) {
+		t.Fatalf("expected 1, got %v", got)
+	}
+}
+
+// TestSetBangTailLoop 用 set! + begin 模拟命令式计数循环，确认尾调用
+// trampoline 和 begin/set! 组合时仍然正确，而不只是 if 的两个分支。
+func TestSetBangTailLoop(t *testing.T) {
+	got := runProgram(t, `
+		(define counter 0)
+		(define bump (lambda (n)
+		  (if (= n 0)
+		      counter
+		      (begin (set! counter (+ counter 1)) (bump (- n 1))))))
+		(bump 1000000)
+	`)
+	if got != Int(1000000) {
+		t.Fatalf("expected 1000000, got %v", got)
+	}
+}
+
+// TestLetAndCond 覆盖 let/let*/cond 的非尾递归场景，确保新增的 special
+// form 本身求值正确，不只是在 TCO 场景下才凑巧工作。
+func TestLetAndCond(t *testing.T) {
+	got := runProgram(t, `
+		(let* ((x 1) (y (+ x 1)))
+		  (cond ((= y 1) 10)
+		        ((= y 2) 20)
+		        (else 30)))
+	`)
+	if got != Int(20) {
+		t.Fatalf("expected 20, got %v", got)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import "testing"
+
+// TestNumericPromotion 覆盖算术提升规则：int 和 int 运算保持 int，只要
+// 有一个操作数是 float 整体就提升成 float，且 (/ 4 2) 能整除时仍是 int。
+func TestNumericPromotion(t *testing.T) {
+	cases := []struct {
+		src  string
+		want Val
+	}{
+		{`(+ 1 2)`, Int(3)},
+		{`(+ 1 2.5)`, Flt(3.5)},
+		{`(* 2 3.0)`, Flt(6)},
+		{`(/ 4 2)`, Int(2)},
+		{`(/ 1 2)`, Flt(0.5)},
+	}
+	for _, c := range cases {
+		if got := runProgram(t, c.src); got != c.want {
+			t.Errorf("%s: expected %v (%T), got %v (%T)", c.src, c.want, c.want, got, got)
+		}
+	}
+}
+
+// TestTruthiness 确认 Scheme 唯一的假值是 #f：0、0.0 和空字符串都是真值，
+// 只有 (if #f ...) 才会走到 else 分支。
+func TestTruthiness(t *testing.T) {
+	cases := []struct {
+		src  string
+		want Val
+	}{
+		{`(if 0 'truthy 'falsy)`, Symbol("truthy")},
+		{`(if 0.0 'truthy 'falsy)`, Symbol("truthy")},
+		{`(if "" 'truthy 'falsy)`, Symbol("truthy")},
+		{`(if #f 'truthy 'falsy)`, Symbol("falsy")},
+	}
+	for _, c := range cases {
+		if got := runProgram(t, c.src); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.src, c.want, got)
+		}
+	}
+}
+
+// TestAndOrShortCircuitAndValue and/or 在遇到第一个能决定结果的值时短路，
+// 返回的是那个值本身（不是强制布尔化的 #t/#f）。
+func TestAndOrShortCircuitAndValue(t *testing.T) {
+	cases := []struct {
+		src  string
+		want Val
+	}{
+		{`(and 1 2 3)`, Int(3)},
+		{`(and 1 #f 3)`, Bool(false)},
+		{`(and)`, Bool(true)},
+		{`(or #f #f 5)`, Int(5)},
+		{`(or #f 2 3)`, Int(2)},
+		{`(or)`, Bool(false)},
+	}
+	for _, c := range cases {
+		if got := runProgram(t, c.src); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.src, c.want, got)
+		}
+	}
+
+	// and 的短路分支不应该求值到会报错的表达式。
+	got := runProgram(t, `(and #f (car '()))`)
+	if got != Bool(false) {
+		t.Errorf("expected short-circuited #f, got %v", got)
+	}
+}
+
+// TestStringBuiltins 覆盖 string-length/string-append/substring/
+// string->symbol。
+func TestStringBuiltins(t *testing.T) {
+	cases := []struct {
+		src  string
+		want Val
+	}{
+		{`(string-length "hello")`, Int(5)},
+		{`(string-append "foo" "bar")`, String("foobar")},
+		{`(substring "hello world" 0 5)`, String("hello")},
+		{`(string->symbol "abc")`, Symbol("abc")},
+	}
+	for _, c := range cases {
+		if got := runProgram(t, c.src); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.src, c.want, got)
+		}
+	}
+}
+
+// TestVectorBuiltins 覆盖 make-vector/vector-ref/vector-set!/
+// vector-length，包括 vector-set! 原地修改共享底层数组对后续 vector-ref
+// 可见。
+func TestVectorBuiltins(t *testing.T) {
+	got := runProgram(t, `
+		(define v (make-vector 3 0))
+		(vector-set! v 1 42)
+		(vector-ref v 1)
+	`)
+	if got != Int(42) {
+		t.Fatalf("expected 42, got %v", got)
+	}
+
+	if got := runProgram(t, `(vector-length (make-vector 5))`); got != Int(5) {
+		t.Errorf("expected 5, got %v", got)
+	}
+}